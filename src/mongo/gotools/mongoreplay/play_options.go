@@ -0,0 +1,30 @@
+package mongoreplay
+
+import (
+	"fmt"
+	"strings"
+)
+
+// PlayOptions holds the connection-target flags Play()'s command line
+// parses. URI and Host are mutually exclusive in practice: a caller
+// supplying a mongodb:// or mongodb+srv:// connection string has no use
+// for --host, mirroring how every other mongo-tools binary treats --uri
+// alongside --host.
+type PlayOptions struct {
+	Host string `long:"host" description:"Mongo host or host:port to replay against"`
+	URI  string `long:"uri" description:"mongodb:// or mongodb+srv:// connection string to replay against, in place of --host"`
+}
+
+// Target resolves the flags in opts into the URITarget Play() dials,
+// preferring URI over Host when both are set so a mongodb+srv:// string's
+// full seed list, replica set name, and TLS default aren't silently
+// dropped in favor of a single host:port.
+func (opts *PlayOptions) Target() (*URITarget, error) {
+	if opts.URI != "" {
+		return ParseURITarget(opts.URI)
+	}
+	if opts.Host != "" {
+		return &URITarget{Seeds: strings.Split(opts.Host, ",")}, nil
+	}
+	return nil, fmt.Errorf("PlayOptions.Target: one of --host or --uri is required")
+}
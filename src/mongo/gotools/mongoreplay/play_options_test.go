@@ -0,0 +1,41 @@
+package mongoreplay
+
+import "testing"
+
+func TestPlayOptionsTargetPrefersURI(t *testing.T) {
+	opts := &PlayOptions{
+		Host: "host1:27017",
+		URI:  "mongodb://host2:27017,host3:27017/?replicaSet=rs0",
+	}
+	target, err := opts.Target()
+	if err != nil {
+		t.Fatalf("Target: %v", err)
+	}
+	if target.ReplicaSet != "rs0" {
+		t.Errorf("ReplicaSet = %q, want %q (expected URI to take precedence over Host)", target.ReplicaSet, "rs0")
+	}
+}
+
+func TestPlayOptionsTargetFromHost(t *testing.T) {
+	opts := &PlayOptions{Host: "host1:27017,host2:27017"}
+	target, err := opts.Target()
+	if err != nil {
+		t.Fatalf("Target: %v", err)
+	}
+	expectedSeeds := []string{"host1:27017", "host2:27017"}
+	if len(target.Seeds) != len(expectedSeeds) {
+		t.Fatalf("Seeds = %v, want %v", target.Seeds, expectedSeeds)
+	}
+	for i := range expectedSeeds {
+		if target.Seeds[i] != expectedSeeds[i] {
+			t.Errorf("Seeds[%d] = %q, want %q", i, target.Seeds[i], expectedSeeds[i])
+		}
+	}
+}
+
+func TestPlayOptionsTargetRequiresHostOrURI(t *testing.T) {
+	opts := &PlayOptions{}
+	if _, err := opts.Target(); err == nil {
+		t.Fatalf("Target: expected an error when neither --host nor --uri is set")
+	}
+}
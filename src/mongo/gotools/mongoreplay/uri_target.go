@@ -0,0 +1,157 @@
+package mongoreplay
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	mgo "github.com/10gen/llmgo"
+)
+
+// srvRediscoveryInterval is the 60-second SRV polling interval the MongoDB
+// URI spec mandates for drivers. A URITarget built from a mongodb+srv:// URI
+// does not poll on its own: it re-resolves its seed list only when Refresh
+// is called.
+const srvRediscoveryInterval = 60 * time.Second
+
+// URITarget resolves a mongodb:// or mongodb+srv:// connection string into
+// the set of options Play() needs to dial a real replica set or sharded
+// cluster, rather than the single host:port or serverConnection stub it
+// otherwise targets.
+type URITarget struct {
+	Seeds          []string
+	ReplicaSet     string
+	ReadPreference string
+	TLS            bool
+	AuthSource     string
+	AppName        string
+	Compressors    []string
+	LoadBalanced   bool
+
+	srv         bool
+	srvHost     string
+	tlsExplicit bool
+
+	// lookupSRV and lookupTXT default to net.LookupSRV/net.LookupTXT; tests
+	// override them to exercise resolveSRV's parsing without a real DNS
+	// resolver.
+	lookupSRV func(service, proto, name string) (cname string, addrs []*net.SRV, err error)
+	lookupTXT func(name string) ([]string, error)
+}
+
+// ParseURITarget parses a mongodb:// or mongodb+srv:// URI into a
+// URITarget. For a +srv URI it performs the initial SRV/TXT lookup so the
+// returned URITarget always carries a concrete seed list; call Refresh
+// later to honor the standard 60-second SRV rediscovery interval.
+func ParseURITarget(uri string) (*URITarget, error) {
+	parsed, err := url.Parse(uri)
+	if err != nil {
+		return nil, fmt.Errorf("ParseURITarget: %v", err)
+	}
+
+	target := &URITarget{
+		lookupSRV: net.LookupSRV,
+		lookupTXT: net.LookupTXT,
+	}
+
+	switch parsed.Scheme {
+	case "mongodb":
+		target.srv = false
+	case "mongodb+srv":
+		target.srv = true
+		target.srvHost = parsed.Host
+	default:
+		return nil, fmt.Errorf("ParseURITarget: unsupported scheme %q", parsed.Scheme)
+	}
+
+	query := parsed.Query()
+	target.ReplicaSet = query.Get("replicaSet")
+	target.ReadPreference = query.Get("readPreference")
+	_, tlsSet := query["tls"]
+	_, sslSet := query["ssl"]
+	target.tlsExplicit = tlsSet || sslSet
+	target.TLS = query.Get("tls") == "true" || query.Get("ssl") == "true"
+	target.AuthSource = query.Get("authSource")
+	target.AppName = query.Get("appName")
+	target.LoadBalanced = query.Get("loadBalanced") == "true"
+	if compressors := query.Get("compressors"); compressors != "" {
+		target.Compressors = strings.Split(compressors, ",")
+	}
+
+	if target.srv {
+		if err := target.resolveSRV(); err != nil {
+			return nil, err
+		}
+	} else {
+		target.Seeds = strings.Split(parsed.Host, ",")
+	}
+
+	return target, nil
+}
+
+// resolveSRV performs the DNS SRV lookup for _mongodb._tcp.<host> to
+// enumerate seeds, and the accompanying TXT lookup for default URI options,
+// as mongodb+srv:// requires.
+func (t *URITarget) resolveSRV() error {
+	_, addrs, err := t.lookupSRV("mongodb", "tcp", t.srvHost)
+	if err != nil {
+		return fmt.Errorf("resolveSRV: SRV lookup for %q: %v", t.srvHost, err)
+	}
+	if len(addrs) == 0 {
+		return fmt.Errorf("resolveSRV: no SRV records found for %q", t.srvHost)
+	}
+
+	seeds := make([]string, 0, len(addrs))
+	for _, addr := range addrs {
+		host := strings.TrimSuffix(addr.Target, ".")
+		seeds = append(seeds, net.JoinHostPort(host, strconv.Itoa(int(addr.Port))))
+	}
+	t.Seeds = seeds
+	if !t.tlsExplicit {
+		t.TLS = true // mongodb+srv implies TLS unless explicitly overridden by the caller
+	}
+
+	txtRecords, err := t.lookupTXT(t.srvHost)
+	if err != nil {
+		// TXT records are optional; absence of a record isn't an error.
+		return nil
+	}
+	for _, record := range txtRecords {
+		values, err := url.ParseQuery(record)
+		if err != nil {
+			continue
+		}
+		if t.ReplicaSet == "" {
+			t.ReplicaSet = values.Get("replicaSet")
+		}
+		if t.AuthSource == "" {
+			t.AuthSource = values.Get("authSource")
+		}
+	}
+	return nil
+}
+
+// Refresh re-resolves a +srv URITarget's seed list. Callers should invoke
+// this roughly every srvRediscoveryInterval for the lifetime of a replay so
+// that topology changes behind a +srv hostname are picked up, matching
+// driver behavior.
+func (t *URITarget) Refresh() error {
+	if !t.srv {
+		return nil
+	}
+	return t.resolveSRV()
+}
+
+// DialInfo builds the mgo.DialInfo that Play() hands to the driver dialer
+// to establish a real connection to the target seeds.
+func (t *URITarget) DialInfo() *mgo.DialInfo {
+	return &mgo.DialInfo{
+		Addrs:          t.Seeds,
+		ReplicaSetName: t.ReplicaSet,
+		Source:         t.AuthSource,
+		AppName:        t.AppName,
+	}
+}
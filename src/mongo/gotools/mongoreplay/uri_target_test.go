@@ -0,0 +1,189 @@
+package mongoreplay
+
+import (
+	"fmt"
+	"net"
+	"reflect"
+	"testing"
+)
+
+func TestParseURITargetMongodb(t *testing.T) {
+	target, err := ParseURITarget("mongodb://host1:27017,host2:27017/?replicaSet=rs0&readPreference=secondary&authSource=admin&appName=mongoreplay&compressors=snappy,zlib")
+	if err != nil {
+		t.Fatalf("ParseURITarget: %v", err)
+	}
+
+	expectedSeeds := []string{"host1:27017", "host2:27017"}
+	if !reflect.DeepEqual(target.Seeds, expectedSeeds) {
+		t.Errorf("Seeds = %v, want %v", target.Seeds, expectedSeeds)
+	}
+	if target.ReplicaSet != "rs0" {
+		t.Errorf("ReplicaSet = %q, want %q", target.ReplicaSet, "rs0")
+	}
+	if target.ReadPreference != "secondary" {
+		t.Errorf("ReadPreference = %q, want %q", target.ReadPreference, "secondary")
+	}
+	if target.AuthSource != "admin" {
+		t.Errorf("AuthSource = %q, want %q", target.AuthSource, "admin")
+	}
+	if target.AppName != "mongoreplay" {
+		t.Errorf("AppName = %q, want %q", target.AppName, "mongoreplay")
+	}
+	expectedCompressors := []string{"snappy", "zlib"}
+	if !reflect.DeepEqual(target.Compressors, expectedCompressors) {
+		t.Errorf("Compressors = %v, want %v", target.Compressors, expectedCompressors)
+	}
+}
+
+// TestParseURITargetTLSFalse checks that a plain mongodb:// URI's explicit
+// tls=false is recorded as an explicit choice rather than left
+// indistinguishable from a URI that never mentioned tls at all.
+func TestParseURITargetTLSFalse(t *testing.T) {
+	target, err := ParseURITarget("mongodb://host1:27017/?tls=false")
+	if err != nil {
+		t.Fatalf("ParseURITarget: %v", err)
+	}
+	if target.TLS {
+		t.Errorf("TLS = true, want false")
+	}
+	if !target.tlsExplicit {
+		t.Errorf("expected tlsExplicit to be true for a URI that sets tls=false")
+	}
+}
+
+func TestParseURITargetUnsupportedScheme(t *testing.T) {
+	if _, err := ParseURITarget("http://host1:27017"); err == nil {
+		t.Fatalf("ParseURITarget: expected an error for an unsupported scheme")
+	}
+}
+
+// TestResolveSRV checks that resolveSRV builds its seed list from the SRV
+// records a lookup returns, defaults TLS on the way mongodb+srv:// requires,
+// and fills in replicaSet/authSource from a TXT record when the URI itself
+// didn't set them.
+func TestResolveSRV(t *testing.T) {
+	target := &URITarget{
+		srv:     true,
+		srvHost: "cluster0.example.com",
+		lookupSRV: func(service, proto, name string) (string, []*net.SRV, error) {
+			if service != "mongodb" || proto != "tcp" || name != "cluster0.example.com" {
+				t.Fatalf("lookupSRV called with unexpected args: %s %s %s", service, proto, name)
+			}
+			return "", []*net.SRV{
+				{Target: "shard00.example.com.", Port: 27017},
+				{Target: "shard01.example.com.", Port: 27017},
+			}, nil
+		},
+		lookupTXT: func(name string) ([]string, error) {
+			return []string{"replicaSet=rs0&authSource=admin"}, nil
+		},
+	}
+
+	if err := target.resolveSRV(); err != nil {
+		t.Fatalf("resolveSRV: %v", err)
+	}
+
+	expectedSeeds := []string{"shard00.example.com:27017", "shard01.example.com:27017"}
+	if !reflect.DeepEqual(target.Seeds, expectedSeeds) {
+		t.Errorf("Seeds = %v, want %v", target.Seeds, expectedSeeds)
+	}
+	if !target.TLS {
+		t.Errorf("expected mongodb+srv to default TLS to true")
+	}
+	if target.ReplicaSet != "rs0" {
+		t.Errorf("ReplicaSet = %q, want %q", target.ReplicaSet, "rs0")
+	}
+	if target.AuthSource != "admin" {
+		t.Errorf("AuthSource = %q, want %q", target.AuthSource, "admin")
+	}
+}
+
+// TestResolveSRVHonorsExplicitTLSFalse checks that resolveSRV doesn't
+// clobber an explicit tls=false/ssl=false from the URI with its usual
+// mongodb+srv default of TLS true.
+func TestResolveSRVHonorsExplicitTLSFalse(t *testing.T) {
+	target := &URITarget{
+		srv:         true,
+		srvHost:     "cluster0.example.com",
+		tlsExplicit: true,
+		lookupSRV: func(service, proto, name string) (string, []*net.SRV, error) {
+			return "", []*net.SRV{{Target: "shard00.example.com.", Port: 27017}}, nil
+		},
+		lookupTXT: func(name string) ([]string, error) { return nil, nil },
+	}
+
+	if err := target.resolveSRV(); err != nil {
+		t.Fatalf("resolveSRV: %v", err)
+	}
+	if target.TLS {
+		t.Errorf("expected an explicit tls=false to survive resolveSRV's default")
+	}
+}
+
+// TestResolveSRVNoRecords checks that resolveSRV errors rather than leaving
+// Seeds empty when the SRV lookup returns no records.
+func TestResolveSRVNoRecords(t *testing.T) {
+	target := &URITarget{
+		srv:     true,
+		srvHost: "cluster0.example.com",
+		lookupSRV: func(service, proto, name string) (string, []*net.SRV, error) {
+			return "", nil, nil
+		},
+		lookupTXT: func(name string) ([]string, error) { return nil, nil },
+	}
+
+	if err := target.resolveSRV(); err == nil {
+		t.Fatalf("resolveSRV: expected an error when no SRV records are found")
+	}
+}
+
+// TestResolveSRVMissingTXTIsNotFatal checks that an absent TXT record (the
+// common case: replicaSet/authSource hints are optional) doesn't fail the
+// lookup.
+func TestResolveSRVMissingTXTIsNotFatal(t *testing.T) {
+	target := &URITarget{
+		srv:     true,
+		srvHost: "cluster0.example.com",
+		lookupSRV: func(service, proto, name string) (string, []*net.SRV, error) {
+			return "", []*net.SRV{{Target: "shard00.example.com.", Port: 27017}}, nil
+		},
+		lookupTXT: func(name string) ([]string, error) {
+			return nil, fmt.Errorf("no TXT record found")
+		},
+	}
+
+	if err := target.resolveSRV(); err != nil {
+		t.Fatalf("resolveSRV: %v", err)
+	}
+	if target.ReplicaSet != "" {
+		t.Errorf("ReplicaSet = %q, want empty without a TXT record", target.ReplicaSet)
+	}
+}
+
+// TestRefresh checks that Refresh re-runs the SRV lookup for a +srv target
+// (picking up a changed seed list) and is a no-op for a plain mongodb://
+// target.
+func TestRefresh(t *testing.T) {
+	calls := 0
+	target := &URITarget{
+		srv:     true,
+		srvHost: "cluster0.example.com",
+		lookupSRV: func(service, proto, name string) (string, []*net.SRV, error) {
+			calls++
+			return "", []*net.SRV{{Target: "shard00.example.com.", Port: 27017}}, nil
+		},
+		lookupTXT: func(name string) ([]string, error) { return nil, nil },
+	}
+
+	if err := target.Refresh(); err != nil {
+		t.Fatalf("Refresh: %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("Refresh on a +srv target called lookupSRV %d times, want 1", calls)
+	}
+
+	plain := &URITarget{srv: false}
+	if err := plain.Refresh(); err != nil {
+		t.Fatalf("Refresh on a plain target: %v", err)
+	}
+}